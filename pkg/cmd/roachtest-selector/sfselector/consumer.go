@@ -13,20 +13,22 @@
 package sfselector
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/csv"
-	"fmt"
 	"io"
-	"os"
 	"strconv"
 
-	"cloud.google.com/go/storage"
+	"github.com/cockroachdb/cockroach/pkg/cmd/roachtest-selector/sfselector/store"
 	"github.com/cockroachdb/cockroach/pkg/cmd/roachtest/registry"
 	"github.com/cockroachdb/errors"
-	"google.golang.org/api/option"
 )
 
+// defaultStoreURL is used when the caller does not supply a storeURL,
+// preserving the selector's historical default of reading from GCS.
+const defaultStoreURL = "gs://" + bucket
+
 // testInfo captures the information available from the csv
 type testInfo struct {
 	selected            bool  // whether a test is selected or not
@@ -40,33 +42,35 @@ type testInfo struct {
 // 3. the test has not been run for a while
 // 4. a subset of the successful tests
 // The individual tests in the input are modified with the skip reason.
+// storeURL selects the backing object store via its scheme (gs://, s3://,
+// azblob:// or file://); if empty, it defaults to the historical GCS bucket.
+// policy decides which candidate tests are actually skipped; if nil, it
+// defaults to AlwaysRunNewPolicy, preserving the selector's historical rule.
+// offline serves the selector CSV from the local cache under
+// ~/.cache/roachtest/sfselector/ instead of contacting storeURL, for use
+// when the selector is run somewhere without network access to it.
 // It returns the number of tests that satisfied the selection criteria and have been modified.
 func ReadTestsToRun(
-	ctx context.Context, tests []registry.TestSpec, cloud, suite string,
+	ctx context.Context, tests []registry.TestSpec, cloud, suite, storeURL string, policy SelectionPolicy, offline bool,
 ) (int, error) {
-	options := []option.ClientOption{option.WithScopes(storage.ScopeReadOnly), option.WithQuotaProject(project)}
-	cj := os.Getenv("GOOGLE_EPHEMERAL_CREDENTIALS")
-	if len(cj) != 0 {
-		options = append(options, option.WithCredentialsJSON([]byte(cj)))
-	} else {
-		fmt.Printf("GOOGLE_EPHEMERAL_CREDENTIALS env is not set.\n")
-	}
-	client, err := storage.NewClient(ctx, options...)
+	if storeURL == "" {
+		storeURL = defaultStoreURL
+	}
+	if policy == nil {
+		policy = AlwaysRunNewPolicy{}
+	}
+	selectorStore, err := store.NewCachedStore(ctx, storeURL, "", offline)
 	if err != nil {
-		return len(tests), errors.NewAssertionErrorWithWrappedErrf(err, "connection to GCS failed")
+		return len(tests), err
 	}
-	defer func() { _ = client.Close() }()
-
-	object := fmt.Sprintf("%s-%s-%s.%s", testsFileLocation, suite, cloud, testsCsvExtension)
-	r, err := client.Bucket(bucket).Object(object).NewReader(ctx)
+	r, err := selectorStore.OpenTestList(ctx, cloud, suite)
 	if err != nil {
-		return len(tests), errors.NewAssertionErrorWithWrappedErrf(err,
-			"failed to get the object %s in bucket %s", object, bucket)
+		return len(tests), err
 	}
 	defer func() { _ = r.Close() }()
 	body, err := io.ReadAll(r)
 	if err != nil {
-		return len(tests), errors.NewAssertionErrorWithWrappedErrf(err, "failed to read CSV from GCS")
+		return len(tests), errors.NewAssertionErrorWithWrappedErrf(err, "failed to read selector CSV")
 	}
 	cr := csv.NewReader(bytes.NewReader(body))
 	data, err := cr.ReadAll()
@@ -84,22 +88,139 @@ func ReadTestsToRun(
 	// 2. AVG_DURATION
 	// 3. TOTAL_RUNS
 	for _, d := range data[1:] {
-		testNamesToRun[d[0]] = &testInfo{
-			selected:            d[1] != "no",
-			avgDurationInMillis: getDuration(d[2]),
-			totalRuns:           getTotalRuns(d[3]),
+		testNamesToRun[d[0]] = rowToTestInfo(d)
+	}
+	return applyPolicy(policy, tests, testNamesToRun, suite), nil
+}
+
+// PagedReader streams the selector CSV for a single (cloud, suite) across
+// repeated calls to Next, keeping the underlying object reader and csv
+// cursor open between them. This makes processing the whole CSV in batches
+// of batchSize rows cost O(rows) total reads, unlike reopening the object
+// and re-skipping every previously-seen row on each call, which would cost
+// O(rows^2 / batchSize). Callers must call Close once done.
+type PagedReader struct {
+	suite string
+
+	r             io.ReadCloser
+	cr            *csv.Reader
+	headerSkipped bool
+}
+
+// NewPagedReader opens the selector CSV for cloud/suite from storeURL (or
+// the historical GCS bucket, if storeURL is empty) and returns a
+// PagedReader positioned before its first data row.
+func NewPagedReader(
+	ctx context.Context, cloud, suite, storeURL string, offline bool,
+) (*PagedReader, error) {
+	if storeURL == "" {
+		storeURL = defaultStoreURL
+	}
+	selectorStore, err := store.NewCachedStore(ctx, storeURL, "", offline)
+	if err != nil {
+		return nil, err
+	}
+	r, err := selectorStore.OpenTestList(ctx, cloud, suite)
+	if err != nil {
+		return nil, err
+	}
+	return &PagedReader{suite: suite, r: r, cr: csv.NewReader(bufio.NewReader(r))}, nil
+}
+
+// Next decodes up to batchSize further rows from the CSV, applies policy
+// (or AlwaysRunNewPolicy if nil) to mark the subset of tests they describe,
+// and returns how many of those tests are left to run. Callers should keep
+// calling Next, accumulating the returned count, until it returns io.EOF to
+// signal that the CSV has been fully consumed.
+func (p *PagedReader) Next(
+	tests []registry.TestSpec, batchSize int64, policy SelectionPolicy,
+) (int, error) {
+	if policy == nil {
+		policy = AlwaysRunNewPolicy{}
+	}
+	if !p.headerSkipped {
+		if _, err := p.cr.Read(); err != nil {
+			if err == io.EOF {
+				return 0, io.EOF
+			}
+			return 0, errors.NewAssertionErrorWithWrappedErrf(err, "failed to read CSV header")
 		}
+		p.headerSkipped = true
 	}
+
+	testNamesToRun := make(map[string]*testInfo)
+	var read int64
+	for read < batchSize {
+		d, err := p.cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, errors.NewAssertionErrorWithWrappedErrf(err, "failed to read CSV data")
+		}
+		testNamesToRun[d[0]] = rowToTestInfo(d)
+		read++
+	}
+	selected := applyPolicyForNamed(policy, tests, testNamesToRun, p.suite)
+	if read < batchSize {
+		return selected, io.EOF
+	}
+	return selected, nil
+}
+
+// Close releases the underlying selector CSV reader.
+func (p *PagedReader) Close() error {
+	return p.r.Close()
+}
+
+// rowToTestInfo decodes a single selector CSV data row into a testInfo.
+func rowToTestInfo(d []string) *testInfo {
+	return &testInfo{
+		selected:            d[1] != "no",
+		avgDurationInMillis: getDuration(d[2]),
+		totalRuns:           getTotalRuns(d[3]),
+	}
+}
+
+// applyPolicy runs policy over tests and testNamesToRun, marking every test
+// it decides to skip, and returns the number of tests that were left to
+// run.
+func applyPolicy(
+	policy SelectionPolicy, tests []registry.TestSpec, testNamesToRun map[string]*testInfo, suite string,
+) int {
+	skip := policy.Select(tests, testNamesToRun, suite)
 	selectedTestsCount := 0
 	for i := range tests {
-		if testShouldBeSkipped(testNamesToRun, tests[i], suite) {
+		if skip[tests[i].Name] {
+			tests[i].Skip = "test selector"
+			tests[i].SkipDetails = "test skipped because it is stable and selective-tests is set."
+		} else {
+			selectedTestsCount++
+		}
+	}
+	return selectedTestsCount
+}
+
+// applyPolicyForNamed is like applyPolicy, but only counts tests whose name
+// appears in testNamesToRun, since a single page of the CSV only describes
+// a subset of the full corpus.
+func applyPolicyForNamed(
+	policy SelectionPolicy, tests []registry.TestSpec, testNamesToRun map[string]*testInfo, suite string,
+) int {
+	skip := policy.Select(tests, testNamesToRun, suite)
+	selectedTestsCount := 0
+	for i := range tests {
+		if _, ok := testNamesToRun[tests[i].Name]; !ok {
+			continue
+		}
+		if skip[tests[i].Name] {
 			tests[i].Skip = "test selector"
 			tests[i].SkipDetails = "test skipped because it is stable and selective-tests is set."
 		} else {
 			selectedTestsCount++
 		}
 	}
-	return selectedTestsCount, nil
+	return selectedTestsCount
 }
 
 // getDuration extracts the duration from the csv data
@@ -0,0 +1,103 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sfselector
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/cmd/roachtest/registry"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSelectorCSV writes a selector CSV for cloud/suite into dir, matching
+// the naming scheme the selector stores expect, and returns a file:// store
+// URL rooted at dir.
+func writeSelectorCSV(t *testing.T, dir, cloud, suite, body string) string {
+	t.Helper()
+	name := "tests-to-run-" + suite + "-" + cloud + ".csv"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(body), 0644))
+	return "file://" + dir
+}
+
+func TestReadTestsToRunSkipsUnselectedStableTests(t *testing.T) {
+	dir := t.TempDir()
+	storeURL := writeSelectorCSV(t, dir, "gce", "nightly",
+		"TEST_NAME,SELECTED,AVG_DURATION,TOTAL_RUNS\n"+
+			"stable,no,1000,50\n"+
+			"flaky,yes,1000,50\n")
+
+	tests := []registry.TestSpec{{Name: "stable"}, {Name: "flaky"}, {Name: "new"}}
+	selected, err := ReadTestsToRun(context.Background(), tests, "gce", "nightly", storeURL, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, 2, selected)
+	require.Equal(t, "test selector", tests[0].Skip)
+	require.Empty(t, tests[1].Skip)
+	require.Empty(t, tests[2].Skip)
+}
+
+func TestReadTestsToRunHeaderOnlyCSV(t *testing.T) {
+	dir := t.TempDir()
+	storeURL := writeSelectorCSV(t, dir, "gce", "nightly", "TEST_NAME,SELECTED,AVG_DURATION,TOTAL_RUNS\n")
+
+	tests := []registry.TestSpec{{Name: "a"}, {Name: "b"}}
+	selected, err := ReadTestsToRun(context.Background(), tests, "gce", "nightly", storeURL, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, len(tests), selected)
+}
+
+func TestPagedReaderStreamsInBatches(t *testing.T) {
+	dir := t.TempDir()
+	storeURL := writeSelectorCSV(t, dir, "gce", "nightly",
+		"TEST_NAME,SELECTED,AVG_DURATION,TOTAL_RUNS\n"+
+			"a,no,1000,50\n"+
+			"b,yes,1000,50\n"+
+			"c,no,1000,50\n")
+
+	reader, err := NewPagedReader(context.Background(), "gce", "nightly", storeURL, false)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+
+	tests := []registry.TestSpec{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	selected, err := reader.Next(tests, 2, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, selected) // "b" is selected, "a" is skipped
+	require.Equal(t, "test selector", tests[0].Skip)
+	require.Empty(t, tests[1].Skip)
+
+	selected, err = reader.Next(tests, 2, nil)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, 0, selected) // "c" is skipped
+	require.Equal(t, "test selector", tests[2].Skip)
+}
+
+func TestGetDurationAndTotalRuns(t *testing.T) {
+	require.EqualValues(t, 1234, getDuration("1234"))
+	require.EqualValues(t, 0, getDuration("not-a-number"))
+	require.Equal(t, 5, getTotalRuns("5"))
+	require.Equal(t, 0, getTotalRuns("not-a-number"))
+}
+
+func TestTestShouldBeSkipped(t *testing.T) {
+	candidates := map[string]*testInfo{
+		"unselected": {selected: false},
+		"selected":   {selected: true},
+	}
+	require.True(t, testShouldBeSkipped(candidates, registry.TestSpec{Name: "unselected"}, "suite"))
+	require.False(t, testShouldBeSkipped(candidates, registry.TestSpec{Name: "selected"}, "suite"))
+	require.False(t, testShouldBeSkipped(candidates, registry.TestSpec{Name: "unknown"}, "suite"))
+	require.False(t, testShouldBeSkipped(
+		candidates, registry.TestSpec{Name: "unselected", Skip: "already skipped"}, "suite"))
+}
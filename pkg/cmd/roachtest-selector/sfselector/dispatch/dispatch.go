@@ -0,0 +1,83 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package dispatch turns the single-process test selection performed by
+// sfselector into a horizontally scalable batch dispatch system. A
+// coordinator splits the selected test list into shards and publishes them
+// to a Pub/Sub topic; worker roachtest runners subscribe, ack the shards
+// they have run, and stream results back to the coordinator.
+package dispatch
+
+// defaultShardSize is the number of test names bundled into a single shard
+// message. It mirrors the batch size historically used when splitting the
+// selector CSV by hand before handing it to parallel CI workers.
+const defaultShardSize = 250
+
+// shardMarkerObject is the name of the GCS object used to track how many
+// shards of the current run have been published, so a restarted coordinator
+// can resume instead of republishing from the beginning.
+const shardMarkerObject = ".shard_num"
+
+// defaultTopic is the Pub/Sub topic shards are published to and subscribed
+// from when the caller does not supply one explicitly.
+const defaultTopic = "gcppubsub://projects/cockroach/topics/roachtest-shards"
+
+// resultsTopicSuffix derives a shard's topic's companion results topic,
+// which workers publish Results to and the coordinator consumes from via
+// ResultsConsumer.
+const resultsTopicSuffix = "-results"
+
+// resultsTopicFor returns the results topic that corresponds to a shards
+// topic, e.g. ".../topics/roachtest-shards" ->
+// ".../topics/roachtest-shards-results".
+func resultsTopicFor(shardsTopic string) string {
+	return shardsTopic + resultsTopicSuffix
+}
+
+// Shard is a bundle of test names dispatched to a single worker. Ordinal
+// identifies the shard's position within the run so progress can be tracked
+// via the shard marker and redelivered shards can be recognized.
+type Shard struct {
+	Ordinal int      `json:"ordinal"`
+	Suite   string   `json:"suite"`
+	Cloud   string   `json:"cloud"`
+	Tests   []string `json:"tests"`
+}
+
+// Result is the outcome of running a single shard, streamed back from a
+// worker to the coordinator once the shard has been fully executed.
+type Result struct {
+	Ordinal int      `json:"ordinal"`
+	Passed  []string `json:"passed"`
+	Failed  []string `json:"failed"`
+}
+
+// shard splits tests into fixed-size shards, preserving order so that
+// Ordinal 0 always covers the first shardSize tests, Ordinal 1 the next,
+// and so on. A shardSize of zero or less means defaultShardSize.
+func shard(tests []string, shardSize int, suite, cloud string) []Shard {
+	if shardSize <= 0 {
+		shardSize = defaultShardSize
+	}
+	var shards []Shard
+	for start := 0; start < len(tests); start += shardSize {
+		end := start + shardSize
+		if end > len(tests) {
+			end = len(tests)
+		}
+		shards = append(shards, Shard{
+			Ordinal: len(shards),
+			Suite:   suite,
+			Cloud:   cloud,
+			Tests:   append([]string(nil), tests[start:end]...),
+		})
+	}
+	return shards
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package dispatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShard(t *testing.T) {
+	tests := make([]string, 7)
+	for i := range tests {
+		tests[i] = string(rune('a' + i))
+	}
+
+	shards := shard(tests, 3, "suite", "cloud")
+	require.Len(t, shards, 3)
+	require.Equal(t, []string{"a", "b", "c"}, shards[0].Tests)
+	require.Equal(t, []string{"d", "e", "f"}, shards[1].Tests)
+	require.Equal(t, []string{"g"}, shards[2].Tests)
+	for i, s := range shards {
+		require.Equal(t, i, s.Ordinal)
+		require.Equal(t, "suite", s.Suite)
+		require.Equal(t, "cloud", s.Cloud)
+	}
+}
+
+func TestShardDefaultsShardSize(t *testing.T) {
+	tests := make([]string, defaultShardSize+1)
+	shards := shard(tests, 0, "suite", "cloud")
+	require.Len(t, shards, 2)
+	require.Len(t, shards[0].Tests, defaultShardSize)
+	require.Len(t, shards[1].Tests, 1)
+}
+
+func TestResultsTopicFor(t *testing.T) {
+	require.Equal(t,
+		"gcppubsub://projects/cockroach/topics/roachtest-shards-results",
+		resultsTopicFor("gcppubsub://projects/cockroach/topics/roachtest-shards"))
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"cloud.google.com/go/storage"
+	"github.com/cockroachdb/errors"
+	"gocloud.dev/pubsub"
+	_ "gocloud.dev/pubsub/gcppubsub"
+)
+
+// Publisher splits a selected test list into shards and publishes each one
+// to a Pub/Sub topic for worker roachtest runners to pick up. It tracks
+// progress in a GCS marker object so a crashed or restarted coordinator can
+// resume publishing where it left off.
+type Publisher struct {
+	Topic      string
+	ShardSize  int
+	MarkerBlob string // bucket/object for the ".shard_num" marker
+	gcs        *storage.Client
+}
+
+// NewPublisher constructs a Publisher that writes its progress marker to
+// markerBucket/shardMarkerObject and publishes shards to topic. If topic is
+// empty, defaultTopic is used.
+func NewPublisher(ctx context.Context, gcs *storage.Client, markerBucket, topic string, shardSize int) *Publisher {
+	if topic == "" {
+		topic = defaultTopic
+	}
+	if shardSize <= 0 {
+		shardSize = defaultShardSize
+	}
+	return &Publisher{
+		Topic:      topic,
+		ShardSize:  shardSize,
+		MarkerBlob: markerBucket + "/" + shardMarkerObject,
+		gcs:        gcs,
+	}
+}
+
+// Publish shards tests and publishes each shard to p.Topic, updating the
+// shard marker after every successful publish. It resumes from the shard
+// ordinal recorded by a previous, interrupted run.
+func (p *Publisher) Publish(ctx context.Context, tests []string, suite, cloud string) (published int, err error) {
+	topic, err := pubsub.OpenTopic(ctx, p.Topic)
+	if err != nil {
+		return 0, errors.NewAssertionErrorWithWrappedErrf(err, "failed to open pubsub topic %s", p.Topic)
+	}
+	defer func() { _ = topic.Shutdown(ctx) }()
+
+	shards := shard(tests, p.ShardSize, suite, cloud)
+	resumeFrom := p.readMarker(ctx)
+	for _, s := range shards {
+		if s.Ordinal < resumeFrom {
+			continue
+		}
+		body, err := json.Marshal(s)
+		if err != nil {
+			return published, errors.NewAssertionErrorWithWrappedErrf(err, "failed to marshal shard %d", s.Ordinal)
+		}
+		if err := topic.Send(ctx, &pubsub.Message{Body: body}); err != nil {
+			return published, errors.NewAssertionErrorWithWrappedErrf(err, "failed to publish shard %d", s.Ordinal)
+		}
+		if err := p.writeMarker(ctx, s.Ordinal+1); err != nil {
+			return published, errors.NewAssertionErrorWithWrappedErrf(err,
+				"failed to record shard marker after publishing shard %d", s.Ordinal)
+		}
+		published++
+	}
+	return published, nil
+}
+
+// readMarker returns the ordinal of the next shard to publish, or 0 if no
+// marker exists yet (a fresh run).
+func (p *Publisher) readMarker(ctx context.Context) int {
+	bucket, object := splitBlob(p.MarkerBlob)
+	r, err := p.gcs.Bucket(bucket).Object(object).NewReader(ctx)
+	if err != nil {
+		return 0
+	}
+	defer func() { _ = r.Close() }()
+	var buf [20]byte
+	n, _ := r.Read(buf[:])
+	next, err := strconv.Atoi(string(buf[:n]))
+	if err != nil {
+		return 0
+	}
+	return next
+}
+
+// writeMarker records ordinal as the next shard that still needs to be
+// published, so a restarted Publisher resumes rather than redispatching
+// already-published shards. The write is only considered successful once
+// the object has been finalized by Close; a failure at either step is
+// returned so the caller does not mistake a lost marker update for a
+// successfully recorded one.
+func (p *Publisher) writeMarker(ctx context.Context, ordinal int) error {
+	bucket, object := splitBlob(p.MarkerBlob)
+	w := p.gcs.Bucket(bucket).Object(object).NewWriter(ctx)
+	if _, err := w.Write([]byte(strconv.Itoa(ordinal))); err != nil {
+		_ = w.Close()
+		return errors.NewAssertionErrorWithWrappedErrf(err, "failed to write shard marker")
+	}
+	if err := w.Close(); err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "failed to finalize shard marker")
+	}
+	return nil
+}
+
+// splitBlob splits a "bucket/object" reference into its two parts.
+func splitBlob(blob string) (bucket, object string) {
+	for i := 0; i < len(blob); i++ {
+		if blob[i] == '/' {
+			return blob[:i], blob[i+1:]
+		}
+	}
+	return blob, shardMarkerObject
+}
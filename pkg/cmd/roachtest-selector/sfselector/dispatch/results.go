@@ -0,0 +1,64 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cockroachdb/errors"
+	"gocloud.dev/pubsub"
+	_ "gocloud.dev/pubsub/gcppubsub"
+)
+
+// ResultsConsumer is the coordinator-side counterpart to Subscriber.
+// PublishResult: it reads the Results workers stream back as they finish
+// running each shard, so the coordinator can track completion of the run
+// without polling the workers directly.
+type ResultsConsumer struct {
+	Subscription string
+
+	sub *pubsub.Subscription
+}
+
+// NewResultsConsumer opens subscription, a Pub/Sub subscription on a
+// Subscriber's ResultsTopic, and returns a ResultsConsumer that reads
+// Results from it until Close is called.
+func NewResultsConsumer(ctx context.Context, subscription string) (*ResultsConsumer, error) {
+	sub, err := pubsub.OpenSubscription(ctx, subscription)
+	if err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err,
+			"failed to open results subscription %s", subscription)
+	}
+	return &ResultsConsumer{Subscription: subscription, sub: sub}, nil
+}
+
+// Next blocks until a Result is available, decodes it, and acks the
+// underlying message.
+func (c *ResultsConsumer) Next(ctx context.Context) (Result, error) {
+	msg, err := c.sub.Receive(ctx)
+	if err != nil {
+		return Result{}, errors.NewAssertionErrorWithWrappedErrf(err,
+			"failed to receive from results subscription %s", c.Subscription)
+	}
+	var result Result
+	if err := json.Unmarshal(msg.Body, &result); err != nil {
+		msg.Nack()
+		return Result{}, errors.NewAssertionErrorWithWrappedErrf(err, "failed to unmarshal result")
+	}
+	msg.Ack()
+	return result, nil
+}
+
+// Close shuts down the underlying subscription.
+func (c *ResultsConsumer) Close(ctx context.Context) error {
+	return c.sub.Shutdown(ctx)
+}
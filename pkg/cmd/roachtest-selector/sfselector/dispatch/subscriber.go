@@ -0,0 +1,103 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cockroachdb/errors"
+	"gocloud.dev/pubsub"
+	_ "gocloud.dev/pubsub/gcppubsub"
+)
+
+// Subscriber pulls shards off a Pub/Sub subscription and hands each one to
+// a worker roachtest runner together with an ack function; the Subscriber
+// never acks a shard itself. A shard the runner has not acked, such as one
+// whose worker crashed mid-shard, is redelivered to another worker once
+// the ack deadline expires. The underlying
+// subscription is opened once and held for the lifetime of the Subscriber,
+// so a worker processing many shards does not reconnect on every Next call.
+type Subscriber struct {
+	Subscription string
+	ResultsTopic string
+
+	sub     *pubsub.Subscription
+	results *pubsub.Topic
+}
+
+// NewSubscriber opens subscription, a provider URL such as
+// "gcppubsub://projects/cockroach/subscriptions/roachtest-shards-worker",
+// and returns a Subscriber that reads from it until Close is called. It
+// also opens resultsTopic, the topic Results are published to once a shard
+// finishes running; if resultsTopic is empty, it is derived from shardsTopic
+// via resultsTopicFor.
+func NewSubscriber(ctx context.Context, subscription, shardsTopic, resultsTopic string) (*Subscriber, error) {
+	sub, err := pubsub.OpenSubscription(ctx, subscription)
+	if err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err,
+			"failed to open pubsub subscription %s", subscription)
+	}
+	if resultsTopic == "" {
+		resultsTopic = resultsTopicFor(shardsTopic)
+	}
+	results, err := pubsub.OpenTopic(ctx, resultsTopic)
+	if err != nil {
+		_ = sub.Shutdown(ctx)
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err,
+			"failed to open results topic %s", resultsTopic)
+	}
+	return &Subscriber{
+		Subscription: subscription,
+		ResultsTopic: resultsTopic,
+		sub:          sub,
+		results:      results,
+	}, nil
+}
+
+// Next blocks until a shard is available, decodes it, and returns an ack
+// function the caller must invoke once it has finished running the shard's
+// tests and is ready to report a Result.
+func (s *Subscriber) Next(ctx context.Context) (shard Shard, ack func(), err error) {
+	msg, err := s.sub.Receive(ctx)
+	if err != nil {
+		return Shard{}, nil, errors.NewAssertionErrorWithWrappedErrf(err,
+			"failed to receive from subscription %s", s.Subscription)
+	}
+	var sh Shard
+	if err := json.Unmarshal(msg.Body, &sh); err != nil {
+		msg.Nack()
+		return Shard{}, nil, errors.NewAssertionErrorWithWrappedErrf(err, "failed to unmarshal shard")
+	}
+	return sh, msg.Ack, nil
+}
+
+// PublishResult streams a shard's Result back to the coordinator on
+// s.ResultsTopic, so it can track completion independently of the ack the
+// worker sends for the shard itself.
+func (s *Subscriber) PublishResult(ctx context.Context, result Result) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "failed to marshal result for shard %d", result.Ordinal)
+	}
+	if err := s.results.Send(ctx, &pubsub.Message{Body: body}); err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "failed to publish result for shard %d", result.Ordinal)
+	}
+	return nil
+}
+
+// Close shuts down the underlying subscription and the results topic.
+func (s *Subscriber) Close(ctx context.Context) error {
+	if err := s.sub.Shutdown(ctx); err != nil {
+		return err
+	}
+	return s.results.Shutdown(ctx)
+}
@@ -0,0 +1,206 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sfselector
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/cmd/roachtest/registry"
+	"github.com/cockroachdb/errors"
+)
+
+// SelectionPolicy decides, for a batch of candidate tests described by
+// candidates, which of their names should be skipped for suite. Policies
+// are composable: SelectionPolicies chains several of them, skipping a
+// test if any policy in the chain would skip it.
+type SelectionPolicy interface {
+	Select(tests []registry.TestSpec, candidates map[string]*testInfo, suite string) map[string]bool
+}
+
+// SelectionPolicies runs several SelectionPolicy implementations in order
+// and skips a test when any one of them would skip it.
+type SelectionPolicies []SelectionPolicy
+
+// Select implements SelectionPolicy.
+func (ps SelectionPolicies) Select(
+	tests []registry.TestSpec, candidates map[string]*testInfo, suite string,
+) map[string]bool {
+	skip := make(map[string]bool)
+	for _, p := range ps {
+		for name := range p.Select(tests, candidates, suite) {
+			skip[name] = true
+		}
+	}
+	return skip
+}
+
+// AlwaysRunNewPolicy is the selection rule the selector has always applied:
+// a test is skipped only once the selector CSV has seen it and marked it
+// not selected; new tests, and tests that have opted out of selection for
+// suite, are always run.
+type AlwaysRunNewPolicy struct{}
+
+// Select implements SelectionPolicy.
+func (AlwaysRunNewPolicy) Select(
+	tests []registry.TestSpec, candidates map[string]*testInfo, suite string,
+) map[string]bool {
+	skip := make(map[string]bool)
+	for _, t := range tests {
+		if testShouldBeSkipped(candidates, t, suite) {
+			skip[t.Name] = true
+		}
+	}
+	return skip
+}
+
+// FlakyBackoffPolicy skips a test once it has accumulated more than MinRuns
+// runs and the selector CSV still has it marked not selected, meaning it
+// has gone that many runs without needing to be rerun due to a failure.
+type FlakyBackoffPolicy struct {
+	MinRuns int
+}
+
+// Select implements SelectionPolicy.
+func (p FlakyBackoffPolicy) Select(
+	tests []registry.TestSpec, candidates map[string]*testInfo, suite string,
+) map[string]bool {
+	skip := make(map[string]bool)
+	for _, t := range tests {
+		if t.Skip != "" || (t.TestSelectionOptOutSuites.IsInitialized() && t.TestSelectionOptOutSuites.Contains(suite)) {
+			continue
+		}
+		info, ok := candidates[t.Name]
+		if ok && !info.selected && info.totalRuns > p.MinRuns {
+			skip[t.Name] = true
+		}
+	}
+	return skip
+}
+
+// estimatedNewTestCostMillis is the predicted duration TimeBudgetPolicy
+// charges a test that has no recorded avgDurationInMillis yet - i.e. one
+// the selector CSV has never seen - so that new tests still count against
+// BudgetMillis instead of running for free and silently blowing past it.
+// It is a conservative guess, roughly in line with a typical roachtest's
+// runtime.
+const estimatedNewTestCostMillis = 60 * 1000
+
+// TimeBudgetPolicy greedily keeps the set of tests with the highest
+// value/cost ratio that fits within BudgetMillis of predicted wall-clock
+// time, and skips the rest. Value is the inverse of how many times a test
+// has already run, and cost is its average duration, so tests that have
+// run less often are favored over ones that have already had plenty of
+// chances to surface regressions. A test the selector has never seen
+// before is charged estimatedNewTestCostMillis so it is still weighed
+// against the budget rather than running unconditionally.
+type TimeBudgetPolicy struct {
+	BudgetMillis int64
+}
+
+// Select implements SelectionPolicy.
+func (p TimeBudgetPolicy) Select(
+	tests []registry.TestSpec, candidates map[string]*testInfo, suite string,
+) map[string]bool {
+	type ranked struct {
+		name  string
+		ratio float64
+		cost  int64
+	}
+	var candidatesByRatio []ranked
+	for _, t := range tests {
+		if t.Skip != "" || (t.TestSelectionOptOutSuites.IsInitialized() && t.TestSelectionOptOutSuites.Contains(suite)) {
+			continue
+		}
+		info, ok := candidates[t.Name]
+		cost := int64(estimatedNewTestCostMillis)
+		totalRuns := 0
+		if ok {
+			cost = info.avgDurationInMillis
+			if cost <= 0 {
+				cost = 1
+			}
+			totalRuns = info.totalRuns
+		}
+		ratio := 1 / float64(totalRuns+1) / float64(cost)
+		candidatesByRatio = append(candidatesByRatio, ranked{t.Name, ratio, cost})
+	}
+	sort.Slice(candidatesByRatio, func(i, j int) bool { return candidatesByRatio[i].ratio > candidatesByRatio[j].ratio })
+
+	included := make(map[string]bool, len(candidatesByRatio))
+	var spent int64
+	for _, c := range candidatesByRatio {
+		if spent+c.cost > p.BudgetMillis {
+			break
+		}
+		spent += c.cost
+		included[c.name] = true
+	}
+	skip := make(map[string]bool)
+	for _, c := range candidatesByRatio {
+		if !included[c.name] {
+			skip[c.name] = true
+		}
+	}
+	return skip
+}
+
+// PriorityWeightedPolicy skips a test whose value/cost score — the same
+// ratio TimeBudgetPolicy ranks by — falls beneath Threshold. Unlike
+// TimeBudgetPolicy it does not target a fixed wall-clock window; it simply
+// keeps every test whose priority clears the bar.
+type PriorityWeightedPolicy struct {
+	Threshold float64
+}
+
+// Select implements SelectionPolicy.
+func (p PriorityWeightedPolicy) Select(
+	tests []registry.TestSpec, candidates map[string]*testInfo, suite string,
+) map[string]bool {
+	skip := make(map[string]bool)
+	for _, t := range tests {
+		if t.Skip != "" || (t.TestSelectionOptOutSuites.IsInitialized() && t.TestSelectionOptOutSuites.Contains(suite)) {
+			continue
+		}
+		info, ok := candidates[t.Name]
+		if !ok {
+			continue
+		}
+		cost := info.avgDurationInMillis
+		if cost <= 0 {
+			cost = 1
+		}
+		ratio := 1 / float64(info.totalRuns+1) / float64(cost)
+		if ratio < p.Threshold {
+			skip[t.Name] = true
+		}
+	}
+	return skip
+}
+
+// PolicyFromFlag builds the SelectionPolicy named by the roachtest
+// --selective-tests-policy flag. budgetMillis, minRuns and threshold are
+// only consulted by the policies that use them.
+func PolicyFromFlag(
+	name string, budgetMillis int64, minRuns int, threshold float64,
+) (SelectionPolicy, error) {
+	switch name {
+	case "", "always-run-new":
+		return AlwaysRunNewPolicy{}, nil
+	case "flaky-backoff":
+		return FlakyBackoffPolicy{MinRuns: minRuns}, nil
+	case "time-budget":
+		return TimeBudgetPolicy{BudgetMillis: budgetMillis}, nil
+	case "priority-weighted":
+		return PriorityWeightedPolicy{Threshold: threshold}, nil
+	default:
+		return nil, errors.Newf("unknown selection policy %q", name)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sfselector
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/cmd/roachtest/registry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlwaysRunNewPolicy(t *testing.T) {
+	tests := []registry.TestSpec{{Name: "stable"}, {Name: "new"}}
+	candidates := map[string]*testInfo{
+		"stable": {selected: false, totalRuns: 50},
+	}
+	skip := AlwaysRunNewPolicy{}.Select(tests, candidates, "suite")
+	require.True(t, skip["stable"])
+	require.False(t, skip["new"])
+}
+
+func TestFlakyBackoffPolicy(t *testing.T) {
+	tests := []registry.TestSpec{{Name: "longStable"}, {Name: "newlyStable"}, {Name: "new"}}
+	candidates := map[string]*testInfo{
+		"longStable":  {selected: false, totalRuns: 100},
+		"newlyStable": {selected: false, totalRuns: 2},
+	}
+	policy := FlakyBackoffPolicy{MinRuns: 10}
+	skip := policy.Select(tests, candidates, "suite")
+	require.True(t, skip["longStable"])
+	require.False(t, skip["newlyStable"])
+	require.False(t, skip["new"])
+}
+
+func TestTimeBudgetPolicyFitsBudget(t *testing.T) {
+	tests := []registry.TestSpec{{Name: "cheap"}, {Name: "expensive"}, {Name: "brandNew"}}
+	candidates := map[string]*testInfo{
+		"cheap":     {totalRuns: 10, avgDurationInMillis: 1000},
+		"expensive": {totalRuns: 10, avgDurationInMillis: 100000},
+	}
+	policy := TimeBudgetPolicy{BudgetMillis: estimatedNewTestCostMillis + 1000}
+	skip := policy.Select(tests, candidates, "suite")
+
+	// brandNew has no recorded duration and must still be charged
+	// estimatedNewTestCostMillis against the budget, rather than being
+	// admitted for free.
+	var totalCost int64
+	if !skip["cheap"] {
+		totalCost += 1000
+	}
+	if !skip["brandNew"] {
+		totalCost += estimatedNewTestCostMillis
+	}
+	require.LessOrEqual(t, totalCost, policy.BudgetMillis)
+	require.True(t, skip["expensive"], "expensive test should not fit in a tight budget")
+}
+
+func TestTimeBudgetPolicyChargesNewTestsAgainstBudget(t *testing.T) {
+	tests := []registry.TestSpec{{Name: "new1"}, {Name: "new2"}}
+	policy := TimeBudgetPolicy{BudgetMillis: estimatedNewTestCostMillis}
+	skip := policy.Select(tests, map[string]*testInfo{}, "suite")
+	// Only one of the two new tests, each costing estimatedNewTestCostMillis,
+	// can fit in a budget sized for exactly one.
+	skipped := 0
+	for _, t := range tests {
+		if skip[t.Name] {
+			skipped++
+		}
+	}
+	require.Equal(t, 1, skipped)
+}
+
+func TestPriorityWeightedPolicy(t *testing.T) {
+	tests := []registry.TestSpec{{Name: "highPriority"}, {Name: "lowPriority"}}
+	candidates := map[string]*testInfo{
+		"highPriority": {totalRuns: 0, avgDurationInMillis: 1000},
+		"lowPriority":  {totalRuns: 1000, avgDurationInMillis: 1000},
+	}
+	policy := PriorityWeightedPolicy{Threshold: 0.0005}
+	skip := policy.Select(tests, candidates, "suite")
+	require.False(t, skip["highPriority"])
+	require.True(t, skip["lowPriority"])
+}
+
+func TestPolicyFromFlag(t *testing.T) {
+	for _, name := range []string{"", "always-run-new", "flaky-backoff", "time-budget", "priority-weighted"} {
+		_, err := PolicyFromFlag(name, 1000, 5, 0.1)
+		require.NoErrorf(t, err, "policy %q should be recognized", name)
+	}
+	_, err := PolicyFromFlag("not-a-policy", 0, 0, 0)
+	require.Error(t, err)
+}
+
+func TestSelectionPoliciesChains(t *testing.T) {
+	tests := []registry.TestSpec{{Name: "a"}, {Name: "b"}}
+	candidates := map[string]*testInfo{}
+	always := stubPolicy{skip: map[string]bool{"a": true}}
+	budget := stubPolicy{skip: map[string]bool{"b": true}}
+	chain := SelectionPolicies{always, budget}
+	skip := chain.Select(tests, candidates, "suite")
+	require.True(t, skip["a"])
+	require.True(t, skip["b"])
+}
+
+type stubPolicy struct {
+	skip map[string]bool
+}
+
+func (p stubPolicy) Select(
+	tests []registry.TestSpec, candidates map[string]*testInfo, suite string,
+) map[string]bool {
+	return p.skip
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/cockroachdb/errors"
+)
+
+// azblobStore is the SelectorStore backed by an Azure Blob Storage
+// container.
+type azblobStore struct {
+	container azblob.ContainerURL
+}
+
+func newAzblobStore(ctx context.Context, rest string) (SelectorStore, error) {
+	container, _, _ := strings.Cut(rest, "/")
+	accountName := os.Getenv("AZURE_STORAGE_ACCOUNT")
+	accountKey := os.Getenv("AZURE_STORAGE_KEY")
+	credential, err := azblob.NewSharedKeyCredential(accountName, accountKey)
+	if err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err, "connection to Azure Blob Storage failed")
+	}
+	pipeline := azblob.NewPipeline(credential, azblob.PipelineOptions{})
+	u, err := url.Parse(fmt.Sprintf("https://%s.blob.core.windows.net/%s", accountName, container))
+	if err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err, "invalid Azure container URL")
+	}
+	return &azblobStore{container: azblob.NewContainerURL(*u, pipeline)}, nil
+}
+
+// OpenTestList implements SelectorStore.
+func (s *azblobStore) OpenTestList(ctx context.Context, cloud, suite string) (io.ReadCloser, error) {
+	object := objectName(cloud, suite)
+	blob := s.container.NewBlockBlobURL(object)
+	resp, err := blob.Download(ctx, 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false, azblob.ClientProvidedKeyOptions{})
+	if err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err, "failed to download blob %s", object)
+	}
+	return resp.Body(azblob.RetryReaderOptions{}), nil
+}
+
+// WriteTestList implements SelectorStore.
+func (s *azblobStore) WriteTestList(ctx context.Context, cloud, suite string, r io.Reader) error {
+	object := objectName(cloud, suite)
+	blob := s.container.NewBlockBlobURL(object)
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "failed to read selector CSV")
+	}
+	if _, err := azblob.UploadBufferToBlockBlob(ctx, body, blob, azblob.UploadToBlockBlobOptions{}); err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "failed to upload blob %s", object)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package store
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/cockroachdb/errors"
+)
+
+// defaultCacheDir is where CachingStore persists downloaded selector CSVs
+// when the caller does not supply a directory of its own.
+const defaultCacheDir = ".cache/roachtest/sfselector"
+
+// Versioner is implemented by SelectorStore backends that can report a
+// version token for an object - such as a GCS generation number or an ETag
+// - without downloading its contents. CachingStore uses it to decide
+// whether a locally cached selector CSV is still fresh.
+type Versioner interface {
+	StatTestList(ctx context.Context, cloud, suite string) (version string, err error)
+}
+
+// CachingStore wraps another SelectorStore with an on-disk cache of the
+// selector CSV, keyed by (cloud, suite). If the wrapped store also
+// implements Versioner, CachingStore issues a metadata-only request before
+// every read and only re-downloads when the remote version has changed;
+// otherwise it reads straight through the wrapped store and caches
+// nothing. In Offline mode it never contacts the wrapped store and serves
+// the last cached copy, or fails if none exists; only a wrapped store that
+// implements Versioner ever fills that cache.
+type CachingStore struct {
+	Inner   SelectorStore
+	Dir     string
+	Offline bool
+}
+
+// NewCachingStore wraps inner with an on-disk cache rooted at dir. If dir
+// is empty, it defaults to ~/.cache/roachtest/sfselector/.
+func NewCachingStore(inner SelectorStore, dir string, offline bool) *CachingStore {
+	if dir == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, defaultCacheDir)
+		} else {
+			dir = defaultCacheDir
+		}
+	}
+	return &CachingStore{Inner: inner, Dir: dir, Offline: offline}
+}
+
+// OpenTestList implements SelectorStore.
+func (c *CachingStore) OpenTestList(ctx context.Context, cloud, suite string) (io.ReadCloser, error) {
+	name, err := localObjectName(cloud, suite)
+	if err != nil {
+		return nil, err
+	}
+	csvPath := filepath.Join(c.Dir, name)
+	versionPath := csvPath + ".version"
+
+	if c.Offline {
+		f, err := os.Open(csvPath)
+		if err != nil {
+			return nil, errors.NewAssertionErrorWithWrappedErrf(err, "no cached selector CSV available offline")
+		}
+		return f, nil
+	}
+
+	if versioner, ok := c.Inner.(Versioner); ok {
+		remoteVersion, err := versioner.StatTestList(ctx, cloud, suite)
+		if err != nil {
+			// The remote store is unreachable; fall back to whatever is
+			// cached, if anything.
+			if f, cacheErr := os.Open(csvPath); cacheErr == nil {
+				return f, nil
+			}
+			return nil, err
+		}
+		if cachedVersion, err := os.ReadFile(versionPath); err == nil && string(cachedVersion) == remoteVersion {
+			if f, err := os.Open(csvPath); err == nil {
+				return f, nil
+			}
+		}
+		body, err := c.downloadAndCache(ctx, cloud, suite, csvPath, versionPath, remoteVersion)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	// The wrapped store cannot report a version, so just always read
+	// through it without ever trusting a stale cache entry.
+	return c.Inner.OpenTestList(ctx, cloud, suite)
+}
+
+// downloadAndCache reads the selector CSV from the wrapped store and
+// persists it, along with its version token, to disk.
+func (c *CachingStore) downloadAndCache(
+	ctx context.Context, cloud, suite, csvPath, versionPath, version string,
+) ([]byte, error) {
+	r, err := c.Inner.OpenTestList(ctx, cloud, suite)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err, "failed to read selector CSV")
+	}
+	if err := os.MkdirAll(c.Dir, 0755); err == nil {
+		_ = os.WriteFile(csvPath, body, 0644)
+		_ = os.WriteFile(versionPath, []byte(version), 0644)
+	}
+	return body, nil
+}
+
+// WriteTestList implements SelectorStore.
+func (c *CachingStore) WriteTestList(ctx context.Context, cloud, suite string, r io.Reader) error {
+	return c.Inner.WriteTestList(ctx, cloud, suite, r)
+}
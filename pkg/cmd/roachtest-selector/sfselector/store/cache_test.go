@@ -0,0 +1,121 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package store
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// versionedStore is a fake SelectorStore that also implements Versioner,
+// letting tests control the remote version and how many times it is
+// actually downloaded from.
+type versionedStore struct {
+	body      string
+	version   string
+	reachable bool
+	opens     int
+}
+
+func (s *versionedStore) OpenTestList(ctx context.Context, cloud, suite string) (io.ReadCloser, error) {
+	if !s.reachable {
+		return nil, errors.New("store unreachable")
+	}
+	s.opens++
+	return io.NopCloser(strings.NewReader(s.body)), nil
+}
+
+func (s *versionedStore) WriteTestList(ctx context.Context, cloud, suite string, r io.Reader) error {
+	return errors.New("not implemented")
+}
+
+func (s *versionedStore) StatTestList(ctx context.Context, cloud, suite string) (string, error) {
+	if !s.reachable {
+		return "", errors.New("store unreachable")
+	}
+	return s.version, nil
+}
+
+func TestCachingStoreReusesCacheWhenVersionUnchanged(t *testing.T) {
+	inner := &versionedStore{body: "a,b\n1,2\n", version: "1", reachable: true}
+	c := NewCachingStore(inner, t.TempDir(), false)
+
+	for i := 0; i < 3; i++ {
+		r, err := c.OpenTestList(context.Background(), "gce", "nightly")
+		require.NoError(t, err)
+		body, err := io.ReadAll(r)
+		require.NoError(t, err)
+		_ = r.Close()
+		require.Equal(t, "a,b\n1,2\n", string(body))
+	}
+	require.Equal(t, 1, inner.opens, "expected only the first OpenTestList to hit the wrapped store")
+}
+
+func TestCachingStoreRedownloadsWhenVersionChanges(t *testing.T) {
+	inner := &versionedStore{body: "a,b\n1,2\n", version: "1", reachable: true}
+	c := NewCachingStore(inner, t.TempDir(), false)
+
+	_, err := c.OpenTestList(context.Background(), "gce", "nightly")
+	require.NoError(t, err)
+	require.Equal(t, 1, inner.opens)
+
+	inner.version = "2"
+	inner.body = "a,b\n3,4\n"
+	r, err := c.OpenTestList(context.Background(), "gce", "nightly")
+	require.NoError(t, err)
+	body, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "a,b\n3,4\n", string(body))
+	require.Equal(t, 2, inner.opens)
+}
+
+func TestCachingStoreFallsBackToCacheWhenUnreachable(t *testing.T) {
+	inner := &versionedStore{body: "a,b\n1,2\n", version: "1", reachable: true}
+	c := NewCachingStore(inner, t.TempDir(), false)
+
+	_, err := c.OpenTestList(context.Background(), "gce", "nightly")
+	require.NoError(t, err)
+
+	inner.reachable = false
+	r, err := c.OpenTestList(context.Background(), "gce", "nightly")
+	require.NoError(t, err)
+	body, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "a,b\n1,2\n", string(body))
+}
+
+func TestCachingStoreOfflineServesCacheWithoutContactingStore(t *testing.T) {
+	inner := &versionedStore{body: "a,b\n1,2\n", version: "1", reachable: true}
+	dir := t.TempDir()
+	c := NewCachingStore(inner, dir, false)
+	_, err := c.OpenTestList(context.Background(), "gce", "nightly")
+	require.NoError(t, err)
+
+	offline := NewCachingStore(inner, dir, true)
+	inner.reachable = false
+	r, err := offline.OpenTestList(context.Background(), "gce", "nightly")
+	require.NoError(t, err)
+	body, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "a,b\n1,2\n", string(body))
+}
+
+func TestCachingStoreOfflineFailsWithoutCache(t *testing.T) {
+	inner := &versionedStore{reachable: false}
+	c := NewCachingStore(inner, t.TempDir(), true)
+	_, err := c.OpenTestList(context.Background(), "gce", "nightly")
+	require.Error(t, err)
+}
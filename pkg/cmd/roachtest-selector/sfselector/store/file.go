@@ -0,0 +1,60 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package store
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/cockroachdb/errors"
+)
+
+// fileStore is the SelectorStore backed by the local filesystem, allowing
+// the selector CSV to be produced and consumed without cloud credentials.
+type fileStore struct {
+	dir string
+}
+
+func newFileStore(dir string) SelectorStore {
+	return &fileStore{dir: dir}
+}
+
+// OpenTestList implements SelectorStore.
+func (s *fileStore) OpenTestList(ctx context.Context, cloud, suite string) (io.ReadCloser, error) {
+	name, err := localObjectName(cloud, suite)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err, "failed to open local selector CSV")
+	}
+	return f, nil
+}
+
+// WriteTestList implements SelectorStore.
+func (s *fileStore) WriteTestList(ctx context.Context, cloud, suite string, r io.Reader) error {
+	name, err := localObjectName(cloud, suite)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(s.dir, name))
+	if err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "failed to create local selector CSV")
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := io.Copy(f, r); err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "failed to write local selector CSV")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/cockroachdb/errors"
+	"google.golang.org/api/option"
+)
+
+// testsFileLocation and testsCsvExtension describe the naming scheme of the
+// selector CSVs, shared by every store implementation.
+const (
+	testsFileLocation = "tests-to-run"
+	testsCsvExtension = "csv"
+)
+
+// project is the GCP project billed for selector reads, used to satisfy the
+// GCS client's quota-project requirement.
+const project = "cockroach-ephemeral"
+
+// gcsStore is the SelectorStore backed by Google Cloud Storage, preserving
+// the behavior ReadTestsToRun had before it was factored out behind
+// SelectorStore.
+type gcsStore struct {
+	client *storage.Client
+	bucket string
+}
+
+func newGCSStore(ctx context.Context, rest string) (SelectorStore, error) {
+	bucket, _, _ := strings.Cut(rest, "/")
+	options := []option.ClientOption{option.WithScopes(storage.ScopeReadOnly), option.WithQuotaProject(project)}
+	cj := os.Getenv("GOOGLE_EPHEMERAL_CREDENTIALS")
+	if len(cj) != 0 {
+		options = append(options, option.WithCredentialsJSON([]byte(cj)))
+	} else {
+		fmt.Printf("GOOGLE_EPHEMERAL_CREDENTIALS env is not set.\n")
+	}
+	client, err := storage.NewClient(ctx, options...)
+	if err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err, "connection to GCS failed")
+	}
+	return &gcsStore{client: client, bucket: bucket}, nil
+}
+
+// OpenTestList implements SelectorStore.
+func (s *gcsStore) OpenTestList(ctx context.Context, cloud, suite string) (io.ReadCloser, error) {
+	object := objectName(cloud, suite)
+	r, err := s.client.Bucket(s.bucket).Object(object).NewReader(ctx)
+	if err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err,
+			"failed to get the object %s in bucket %s", object, s.bucket)
+	}
+	return r, nil
+}
+
+// StatTestList implements Versioner using the object's GCS generation
+// number, so CachingStore can tell whether a cached selector CSV is stale
+// without downloading it.
+func (s *gcsStore) StatTestList(ctx context.Context, cloud, suite string) (string, error) {
+	object := objectName(cloud, suite)
+	attrs, err := s.client.Bucket(s.bucket).Object(object).Attrs(ctx)
+	if err != nil {
+		return "", errors.NewAssertionErrorWithWrappedErrf(err,
+			"failed to stat object %s in bucket %s", object, s.bucket)
+	}
+	return strconv.FormatInt(attrs.Generation, 10), nil
+}
+
+// WriteTestList implements SelectorStore.
+func (s *gcsStore) WriteTestList(ctx context.Context, cloud, suite string, r io.Reader) error {
+	object := objectName(cloud, suite)
+	w := s.client.Bucket(s.bucket).Object(object).NewWriter(ctx)
+	if _, err := io.Copy(w, r); err != nil {
+		_ = w.Close()
+		return errors.NewAssertionErrorWithWrappedErrf(err,
+			"failed to write object %s in bucket %s", object, s.bucket)
+	}
+	if err := w.Close(); err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "failed to finalize object %s", object)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package store
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/cockroachdb/errors"
+)
+
+// s3Store is the SelectorStore backed by an S3 bucket.
+type s3Store struct {
+	client *s3.S3
+	bucket string
+}
+
+func newS3Store(ctx context.Context, rest string) (SelectorStore, error) {
+	bucket, _, _ := strings.Cut(rest, "/")
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err, "connection to S3 failed")
+	}
+	return &s3Store{client: s3.New(sess), bucket: bucket}, nil
+}
+
+// OpenTestList implements SelectorStore.
+func (s *s3Store) OpenTestList(ctx context.Context, cloud, suite string) (io.ReadCloser, error) {
+	object := objectName(cloud, suite)
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(object),
+	})
+	if err != nil {
+		return nil, errors.NewAssertionErrorWithWrappedErrf(err,
+			"failed to get object %s in bucket %s", object, s.bucket)
+	}
+	return out.Body, nil
+}
+
+// WriteTestList implements SelectorStore.
+func (s *s3Store) WriteTestList(ctx context.Context, cloud, suite string, r io.Reader) error {
+	object := objectName(cloud, suite)
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err, "failed to read selector CSV")
+	}
+	_, err = s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(object),
+		Body:   bytes.NewReader(body),
+	})
+	if err != nil {
+		return errors.NewAssertionErrorWithWrappedErrf(err,
+			"failed to put object %s in bucket %s", object, s.bucket)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package store abstracts the object store that selector CSVs are read
+// from and written to, so roachtest runners can select tests in CI
+// environments that do not carry Google credentials.
+package store
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// SelectorStore reads and writes the selector CSV for a given (cloud,
+// suite) pair, independent of the backing object store.
+type SelectorStore interface {
+	// OpenTestList opens the selector CSV for cloud/suite for reading. The
+	// caller is responsible for closing the returned reader.
+	OpenTestList(ctx context.Context, cloud, suite string) (io.ReadCloser, error)
+	// WriteTestList writes the selector CSV for cloud/suite, overwriting any
+	// existing object.
+	WriteTestList(ctx context.Context, cloud, suite string, r io.Reader) error
+}
+
+// objectName derives the well-known object name for a selector CSV from its
+// cloud and suite, matching the naming scheme the selector has always used.
+func objectName(cloud, suite string) string {
+	return fmt.Sprintf("%s-%s-%s.%s", testsFileLocation, suite, cloud, testsCsvExtension)
+}
+
+// localObjectName is like objectName, but additionally validates that cloud
+// and suite are safe to use as a local filesystem path component. Unlike a
+// cloud object key, a local path can be escaped with "/" or "..", so the
+// filesystem-backed stores must not hand them to filepath.Join unchecked.
+func localObjectName(cloud, suite string) (string, error) {
+	if err := validatePathComponent("cloud", cloud); err != nil {
+		return "", err
+	}
+	if err := validatePathComponent("suite", suite); err != nil {
+		return "", err
+	}
+	return objectName(cloud, suite), nil
+}
+
+// validatePathComponent rejects a value that could let a local store escape
+// its configured directory if used as a path component, such as one
+// containing a path separator or a ".." traversal.
+func validatePathComponent(field, value string) error {
+	if value == "" {
+		return errors.Newf("%s must not be empty", field)
+	}
+	if value == "." || value == ".." || strings.ContainsAny(value, `/\`) {
+		return errors.Newf("%s %q is not a valid path component", field, value)
+	}
+	return nil
+}
+
+// NewStore returns the SelectorStore implementation for rawURL's scheme:
+// gs:// for GCS, s3:// for S3, azblob:// for Azure Blob Storage, and
+// file:// (or a bare path) for the local filesystem.
+func NewStore(ctx context.Context, rawURL string) (SelectorStore, error) {
+	scheme, rest, ok := strings.Cut(rawURL, "://")
+	if !ok {
+		// No scheme supplied: treat the whole string as a local directory.
+		return newFileStore(rawURL), nil
+	}
+	switch scheme {
+	case "gs":
+		return newGCSStore(ctx, rest)
+	case "s3":
+		return newS3Store(ctx, rest)
+	case "azblob":
+		return newAzblobStore(ctx, rest)
+	case "file":
+		return newFileStore(rest), nil
+	default:
+		return nil, errors.Newf("unsupported selector store scheme %q", scheme)
+	}
+}
+
+// NewCachedStore is like NewStore, but wraps the resulting SelectorStore in
+// a CachingStore backed by cacheDir (or ~/.cache/roachtest/sfselector/ when
+// cacheDir is empty), so repeated calls for the same (cloud, suite) avoid
+// redundant downloads. When offline is set, the returned store never
+// contacts rawURL and instead serves the last cached selector CSV.
+func NewCachedStore(ctx context.Context, rawURL, cacheDir string, offline bool) (SelectorStore, error) {
+	inner, err := NewStore(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return NewCachingStore(inner, cacheDir, offline), nil
+}
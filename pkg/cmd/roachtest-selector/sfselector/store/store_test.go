@@ -0,0 +1,64 @@
+// Copyright 2024 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package store
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStoreUnsupportedScheme(t *testing.T) {
+	_, err := NewStore(context.Background(), "ftp://somewhere")
+	require.Error(t, err)
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStore(context.Background(), "file://"+dir)
+	require.NoError(t, err)
+
+	require.NoError(t, s.WriteTestList(context.Background(), "gce", "nightly", strings.NewReader("a,b\n1,2\n")))
+
+	r, err := s.OpenTestList(context.Background(), "gce", "nightly")
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+	body, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "a,b\n1,2\n", string(body))
+
+	_, err = os.Stat(filepath.Join(dir, objectName("gce", "nightly")))
+	require.NoError(t, err)
+}
+
+func TestLocalObjectNameRejectsPathEscapes(t *testing.T) {
+	for _, tc := range []struct {
+		cloud, suite string
+	}{
+		{"../etc", "nightly"},
+		{"gce", "../../passwd"},
+		{"gce/evil", "nightly"},
+		{"", "nightly"},
+		{"gce", ""},
+	} {
+		_, err := localObjectName(tc.cloud, tc.suite)
+		require.Errorf(t, err, "expected cloud=%q suite=%q to be rejected", tc.cloud, tc.suite)
+	}
+
+	name, err := localObjectName("gce", "nightly")
+	require.NoError(t, err)
+	require.Equal(t, objectName("gce", "nightly"), name)
+}